@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attribute // import "go.opentelemetry.io/otel/api/attribute"
+
+import "go.opentelemetry.io/otel/api/core"
+
+// Iterator allows iterating over the (sorted, de-duplicated)
+// KeyValues of a Set. The typical use of the iterator is as
+// follows:
+//
+//	iter := set.Iter()
+//	for iter.Next() {
+//	  label := iter.Label()
+//	  // or, if we need an index:
+//	  // idx, label := iter.IndexedLabel()
+//	  // do something with label
+//	}
+type Iterator struct {
+	set *Set
+	idx int
+}
+
+// NewIterator creates an Iterator over the provided Set.
+func NewIterator(set *Set) Iterator {
+	return Iterator{
+		set: set,
+		idx: -1,
+	}
+}
+
+// Next moves the iterator to the next position. Returns false if
+// there are no more labels.
+func (i *Iterator) Next() bool {
+	i.idx++
+	return i.idx < i.Len()
+}
+
+// Label returns the current KeyValue. Must be called only after
+// Next returns true.
+func (i *Iterator) Label() core.KeyValue {
+	kv, _ := i.set.Get(i.idx)
+	return kv
+}
+
+// IndexedLabel returns the current index and KeyValue. Must be
+// called only after Next returns true.
+func (i *Iterator) IndexedLabel() (int, core.KeyValue) {
+	return i.idx, i.Label()
+}
+
+// Len returns the number of labels in the underlying Set.
+func (i *Iterator) Len() int {
+	return i.set.Len()
+}
+
+// IteratorToSlice creates a slice of KeyValues from the passed
+// iterator. The iterator is reset to the beginning before creating
+// the slice.
+func IteratorToSlice(iter Iterator) []core.KeyValue {
+	l := iter.Len()
+	if l == 0 {
+		return nil
+	}
+	iter.idx = -1
+	slice := make([]core.KeyValue, 0, l)
+	for iter.Next() {
+		slice = append(slice, iter.Label())
+	}
+	return slice
+}
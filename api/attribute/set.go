@@ -0,0 +1,227 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package attribute provides a canonical, immutable representation
+// of a set of core.KeyValue pairs, used throughout the API and SDK
+// wherever a set of labels needs to be compared, hashed, or used as
+// a map key.
+package attribute // import "go.opentelemetry.io/otel/api/attribute"
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel/api/core"
+)
+
+// Set is an immutable set of de-duplicated core.KeyValues, sorted by
+// Key, that supports hashing and comparison via Equivalent. A zero
+// Set is empty and valid.
+type Set struct {
+	equivalent Distinct
+
+	// cache holds the lazily computed, per-Encoder encoded form of
+	// this Set. It is shared by value copies of Set so that the
+	// encoding is computed at most once regardless of how many
+	// export pipelines request it.
+	cache *encoderCache
+}
+
+// Distinct is a comparable value that uniquely identifies a Set.
+// Distinct is suitable for use as a map key or for equality
+// comparison with ==.
+type Distinct struct {
+	iface interface{}
+}
+
+// Sortable is a scratch slice used by NewSetWithSortable to avoid an
+// allocation while sorting. Its contents are not meaningful between
+// calls; it exists only to be reused.
+type Sortable []core.KeyValue
+
+var _ sort.Interface = (*Sortable)(nil)
+
+var keyValueType = reflect.TypeOf(core.KeyValue{})
+
+// Len implements sort.Interface.
+func (l *Sortable) Len() int {
+	return len(*l)
+}
+
+// Swap implements sort.Interface.
+func (l *Sortable) Swap(i, j int) {
+	(*l)[i], (*l)[j] = (*l)[j], (*l)[i]
+}
+
+// Less implements sort.Interface.
+func (l *Sortable) Less(i, j int) bool {
+	return (*l)[i].Key < (*l)[j].Key
+}
+
+// NewSet returns a new Set built from kvs, after sorting by key and
+// removing duplicate keys (the last value for a given key wins).
+// For a fast path that avoids allocating a new Sortable, see
+// NewSetWithSortable.
+func NewSet(kvs ...core.KeyValue) Set {
+	s, _ := NewSetWithSortable(kvs, new(Sortable))
+	return s
+}
+
+// NewSetWithSortable returns a new Set built from kvs, using tmp as
+// scratch storage to sort kvs in place. This avoids an allocation in
+// the common case where the caller already owns a reusable
+// Sortable, e.g., on a per-instrument hot path. The second return
+// value is the de-duplicated, sorted slice from which the Set was
+// built; it aliases kvs. The Set keeps its own copy of the values.
+func NewSetWithSortable(kvs []core.KeyValue, tmp *Sortable) (Set, []core.KeyValue) {
+	if len(kvs) == 0 {
+		return Set{cache: &encoderCache{}}, kvs
+	}
+
+	*tmp = kvs
+	sort.Stable(tmp)
+	*tmp = nil
+
+	position := len(kvs) - 1
+	offset := position - 1
+
+	// Filter out duplicate keys, keeping the last value for each,
+	// scanning from the end since kvs is now sorted and stable.
+	for ; offset >= 0; offset-- {
+		if kvs[offset].Key == kvs[position].Key {
+			continue
+		}
+		position--
+		kvs[position] = kvs[offset]
+	}
+	kvs = kvs[position:]
+
+	return Set{equivalent: computeDistinct(kvs), cache: &encoderCache{}}, kvs
+}
+
+// Len returns the number of elements in this set.
+func (l *Set) Len() int {
+	if l.equivalent.iface == nil {
+		return 0
+	}
+	return reflect.ValueOf(l.equivalent.iface).Len()
+}
+
+// Get returns the KeyValue at the provided index, sorted by key.
+func (l *Set) Get(idx int) (core.KeyValue, bool) {
+	if l.equivalent.iface == nil {
+		return core.KeyValue{}, false
+	}
+	value := reflect.ValueOf(l.equivalent.iface)
+	if idx < 0 || idx >= value.Len() {
+		return core.KeyValue{}, false
+	}
+	return value.Index(idx).Interface().(core.KeyValue), true
+}
+
+// Iter returns an iterator over the Set, in sorted order.
+func (l *Set) Iter() Iterator {
+	return NewIterator(l)
+}
+
+// ToSlice returns a new slice holding the sorted, de-duplicated
+// KeyValues of this Set, or nil if the Set is empty.
+func (l *Set) ToSlice() []core.KeyValue {
+	return IteratorToSlice(l.Iter())
+}
+
+// Equivalent returns a value that may be used as a map key or
+// compared with == to test whether two Sets contain the same
+// elements.
+func (l *Set) Equivalent() Distinct {
+	return l.equivalent
+}
+
+// Equals returns true if the argument Set is equivalent to this
+// Set, i.e., contains the same de-duplicated key-value pairs.
+func (l *Set) Equals(o *Set) bool {
+	return l.equivalent == o.equivalent
+}
+
+// computeDistinct builds a comparable Distinct value from a sorted,
+// de-duplicated slice of KeyValues by copying it into a fixed-size
+// array of the appropriate length. Go arrays, unlike slices, are
+// comparable, which makes the resulting interface{} a legal map key
+// and allows Distinct values to be compared with ==.
+func computeDistinct(kvs []core.KeyValue) Distinct {
+	array := reflect.New(reflect.ArrayOf(len(kvs), keyValueType)).Elem()
+	for i, kv := range kvs {
+		array.Index(i).Set(reflect.ValueOf(kv))
+	}
+	return Distinct{
+		iface: array.Interface(),
+	}
+}
+
+// Encoder encodes a Set into a string, e.g., to serialize labels
+// for a text-based export format. Implementations are expected to
+// obtain their ID once per process, e.g., via NewLabelEncoderID in
+// the sdk/export/metric package.
+type Encoder interface {
+	// Encode is called (concurrently) in instrumentation context.
+	Encode(Iterator) string
+
+	// ID should return a unique positive number associated with the
+	// encoder. Stateless encoders return the same ID regardless of
+	// instance; stateful encoders return an ID that depends on
+	// their configuration.
+	ID() int64
+}
+
+type cachedEncoding struct {
+	id      int64
+	encoded string
+}
+
+// encoderCache holds a small set of (encoder ID, encoded string)
+// pairs computed lazily for a Set, so that repeated calls to
+// Encoded with the same Encoder across multiple export pipelines
+// avoid re-encoding the Set's labels.
+type encoderCache struct {
+	mu    sync.Mutex
+	cache []cachedEncoding
+}
+
+// Encoded returns the encoded representation of this Set using the
+// provided Encoder, computing and caching it on first use.
+func (l *Set) Encoded(encoder Encoder) string {
+	if l.Len() == 0 || encoder == nil {
+		return ""
+	}
+
+	id := encoder.ID()
+
+	l.cache.mu.Lock()
+	for _, ce := range l.cache.cache {
+		if ce.id == id {
+			l.cache.mu.Unlock()
+			return ce.encoded
+		}
+	}
+	l.cache.mu.Unlock()
+
+	encoded := encoder.Encode(l.Iter())
+
+	l.cache.mu.Lock()
+	l.cache.cache = append(l.cache.cache, cachedEncoding{id: id, encoded: encoded})
+	l.cache.mu.Unlock()
+
+	return encoded
+}
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attribute
+
+import (
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/api/core"
+)
+
+func TestNewSetDedupsKeepingLastValue(t *testing.T) {
+	s := NewSet(
+		core.Key("a").String("first"),
+		core.Key("b").Int(2),
+		core.Key("a").String("second"),
+	)
+
+	if got := s.Len(); got != 2 {
+		t.Fatalf("expected 2 de-duplicated keys, got %d", got)
+	}
+
+	kv, ok := s.Get(0)
+	if !ok || kv.Key != core.Key("a") || kv.Value.Emit() != "second" {
+		t.Fatalf("expected (a, second) to win, got %v (ok=%v)", kv, ok)
+	}
+}
+
+func TestNewSetSortsByKey(t *testing.T) {
+	s := NewSet(
+		core.Key("b").Int(1),
+		core.Key("a").Int(2),
+		core.Key("c").Int(3),
+	)
+
+	var keys []string
+	iter := s.Iter()
+	for iter.Next() {
+		keys = append(keys, string(iter.Label().Key))
+	}
+	if got := strings.Join(keys, ","); got != "a,b,c" {
+		t.Fatalf("expected sorted keys a,b,c, got %s", got)
+	}
+}
+
+func TestSetEquivalentAndEquals(t *testing.T) {
+	a := NewSet(core.Key("a").String("1"), core.Key("b").Int(2))
+	b := NewSet(core.Key("b").Int(2), core.Key("a").String("1"))
+	c := NewSet(core.Key("a").String("1"))
+
+	if !a.Equals(&b) {
+		t.Fatalf("expected sets with the same pairs in different order to be equal")
+	}
+	if a.Equivalent() != b.Equivalent() {
+		t.Fatalf("expected Equivalent() to match for equal sets")
+	}
+	if a.Equals(&c) {
+		t.Fatalf("expected sets with different contents to be unequal")
+	}
+	if a.Equivalent() == c.Equivalent() {
+		t.Fatalf("expected Equivalent() to differ for unequal sets")
+	}
+}
+
+func TestEmptySet(t *testing.T) {
+	var s Set
+	if s.Len() != 0 {
+		t.Fatalf("expected zero Set to be empty")
+	}
+	if _, ok := s.Get(0); ok {
+		t.Fatalf("expected Get on an empty Set to fail")
+	}
+}
+
+// countingEncoder counts how many times Encode is actually invoked,
+// so tests can verify Set.Encoded caches its result per Encoder ID.
+type countingEncoder struct {
+	id    int64
+	calls int
+}
+
+func (e *countingEncoder) Encode(iter Iterator) string {
+	e.calls++
+	var parts []string
+	for iter.Next() {
+		kv := iter.Label()
+		parts = append(parts, string(kv.Key)+"="+kv.Value.Emit())
+	}
+	return strings.Join(parts, ",")
+}
+
+func (e *countingEncoder) ID() int64 {
+	return e.id
+}
+
+func TestSetEncodedCachesPerEncoder(t *testing.T) {
+	s := NewSet(core.Key("a").String("1"), core.Key("b").Int(2))
+
+	enc1 := &countingEncoder{id: 1}
+	enc2 := &countingEncoder{id: 2}
+
+	first := s.Encoded(enc1)
+	second := s.Encoded(enc1)
+	if first != second {
+		t.Fatalf("expected repeated Encoded calls to return the same string")
+	}
+	if enc1.calls != 1 {
+		t.Fatalf("expected Encode to be invoked exactly once for enc1, got %d", enc1.calls)
+	}
+
+	if got := s.Encoded(enc2); got == "" {
+		t.Fatalf("expected a non-empty encoding from a distinct encoder")
+	}
+	if enc2.calls != 1 {
+		t.Fatalf("expected Encode to be invoked exactly once for enc2, got %d", enc2.calls)
+	}
+	if enc1.calls != 1 {
+		t.Fatalf("expected enc1's cached entry to be unaffected by enc2, got %d calls", enc1.calls)
+	}
+}
+
+func TestSetEncodedEmpty(t *testing.T) {
+	var s Set
+	if got := s.Encoded(&countingEncoder{id: 1}); got != "" {
+		t.Fatalf("expected empty Set to encode to an empty string, got %q", got)
+	}
+	if got := s.Encoded(nil); got != "" {
+		t.Fatalf("expected a nil Encoder to encode to an empty string, got %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aggregation defines the set of supported aggregations and
+// the corresponding interfaces for querying them.  This is a
+// leaf-level package used to avoid circular imports between the
+// concrete aggregator implementations and the export interfaces.
+package aggregation // import "go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/api/core"
+)
+
+// Kind identifies the aggregation kind in use for a given metric
+// instrument, e.g., the kind of data points it produces.  This is
+// used by an ExportKindSelector to decide on an appropriate
+// ExportKind for a given aggregation, since not all aggregations
+// support all export kinds (e.g., LastValue is neither a sum nor
+// commutative, so it always passes through).
+type Kind string
+
+const (
+	// SumKind indicates that the aggregation is a simple running
+	// sum, as for a Counter.
+	SumKind Kind = "Sum"
+
+	// MinMaxSumCountKind indicates an aggregation that tracks the
+	// minimum, maximum, sum, and count of measurements.
+	MinMaxSumCountKind Kind = "MinMaxSumCount"
+
+	// HistogramKind indicates an aggregation that tracks counts in
+	// pre-determined buckets, along with the sum and count of
+	// measurements.
+	HistogramKind Kind = "Histogram"
+
+	// LastValueKind indicates an aggregation that only keeps the
+	// last value observed, as for an Observer gauge.
+	LastValueKind Kind = "Lastvalue"
+
+	// ExactKind indicates an aggregation that keeps an exact record
+	// of all measurements.
+	ExactKind Kind = "Exact"
+)
+
+// String returns the string form of an aggregation Kind.
+func (k Kind) String() string {
+	return string(k)
+}
+
+// Aggregation is implemented by an exported checkpoint that can
+// report its own Kind. Exporters use this to select which of the
+// below interfaces to query for a given Record's checkpoint.
+type Aggregation interface {
+	// Kind returns the Kind of aggregation this checkpoint holds.
+	Kind() Kind
+}
+
+// Sum is an aggregation that holds a running sum of measurements.
+type Sum interface {
+	Aggregation
+	Sum() (core.Number, error)
+}
+
+// LastValue is an aggregation that holds the last observed value
+// along with the time it was observed.
+type LastValue interface {
+	Aggregation
+	LastValue() (core.Number, time.Time, error)
+}
+
+// MinMaxSumCount is an aggregation that holds the minimum, maximum,
+// sum, and count of its observed measurements.
+type MinMaxSumCount interface {
+	Aggregation
+	Min() (core.Number, error)
+	Max() (core.Number, error)
+	Sum() (core.Number, error)
+	Count() (int64, error)
+}
+
+// Buckets describes histogram buckets, with Counts[i] holding the
+// number of measurements less than or equal to Boundaries[i], except
+// for the final count, which holds the number of measurements
+// greater than the last boundary.
+type Buckets struct {
+	// Boundaries are ordered bucket boundaries, len(Boundaries) ==
+	// len(Counts)-1.
+	Boundaries []float64
+
+	// Counts is the number of measurements falling in each bucket.
+	Counts []uint64
+}
+
+// Histogram is an aggregation that summarizes a distribution of
+// measurements as a histogram, along with its sum and count.
+type Histogram interface {
+	Aggregation
+	Sum() (core.Number, error)
+	Count() (int64, error)
+	Histogram() (Buckets, error)
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+)
+
+// fakeAggregator is a minimal Aggregator used to exercise
+// NewAggregatorSlice without depending on a concrete aggregator
+// implementation.
+type fakeAggregator struct {
+	updates int
+}
+
+func (a *fakeAggregator) Update(context.Context, core.Number, *metric.Descriptor) error {
+	a.updates++
+	return nil
+}
+func (a *fakeAggregator) Checkpoint(context.Context, *metric.Descriptor)        {}
+func (a *fakeAggregator) Merge(Aggregator, *metric.Descriptor) error            { return nil }
+func (a *fakeAggregator) SynchronizedMove(Aggregator, *metric.Descriptor) error { return nil }
+
+var _ Aggregator = (*fakeAggregator)(nil)
+
+func TestNewAggregatorSliceFillsIndependentSlots(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		aggs := make([]*Aggregator, n)
+		ptrs := make([]*Aggregator, n)
+		for i := range aggs {
+			ptrs[i] = new(Aggregator)
+		}
+
+		NewAggregatorSlice(&fakeAggregator{}, ptrs)
+
+		for i, p := range ptrs {
+			if *p == nil {
+				t.Fatalf("aggPtrs[%d] was not filled", i)
+			}
+		}
+
+		// Every slot must hold a distinct Aggregator, and mutating
+		// one must not affect its neighbors, i.e., each points at a
+		// distinct element of the shared backing array rather than
+		// a single shared instance.
+		for i := range ptrs {
+			if err := (*ptrs[i]).Update(context.Background(), core.Number(0), nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		for i := range ptrs {
+			fa := (*ptrs[i]).(*fakeAggregator)
+			if fa.updates != 1 {
+				t.Fatalf("slot %d: expected exactly one update, got %d (slots are not independent)", i, fa.updates)
+			}
+		}
+		for i := 0; i < len(ptrs); i++ {
+			for j := i + 1; j < len(ptrs); j++ {
+				if *ptrs[i] == *ptrs[j] {
+					t.Fatalf("slots %d and %d hold the same Aggregator instance", i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestNewAggregatorSliceEmpty(t *testing.T) {
+	// Must not panic when there are no pipelines to fan out to.
+	NewAggregatorSlice(&fakeAggregator{}, nil)
+}
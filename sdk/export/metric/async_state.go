@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric // import "go.opentelemetry.io/otel/sdk/export/metric"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/api/attribute"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+)
+
+// asyncStateKey identifies a single asynchronous instrument's
+// observation for one label set during one collection round.
+type asyncStateKey struct {
+	descriptor *metric.Descriptor
+	labels     attribute.Distinct
+}
+
+type asyncObservation struct {
+	descriptor *metric.Descriptor
+	labels     *attribute.Set
+	number     core.Number
+}
+
+// AsyncInstrumentState wraps an AsyncRunner to de-duplicate the
+// observations its callback(s) report during a single collection
+// round: if the same instrument is observed more than once for the
+// same label set (e.g. because a batch callback is re-entrant, or a
+// user calls Observe twice by mistake), only the last observation
+// for that (descriptor, labels) pair is delivered downstream, which
+// matches the synchronous record path's last-write-wins semantics.
+//
+// AsyncInstrumentState is not safe for concurrent use; the SDK is
+// expected to serialize collection rounds, invoking Run once per
+// round from a single goroutine.
+type AsyncInstrumentState struct {
+	runner AsyncRunner
+	seen   map[asyncStateKey]*asyncObservation
+	order  []asyncStateKey
+}
+
+// NewAsyncInstrumentState returns an AsyncInstrumentState that
+// invokes runner's callback(s) and de-duplicates what they report.
+func NewAsyncInstrumentState(runner AsyncRunner) *AsyncInstrumentState {
+	return &AsyncInstrumentState{
+		runner: runner,
+	}
+}
+
+// Run invokes the wrapped AsyncRunner for one collection round,
+// forwarding each distinct (descriptor, labels) observation it
+// reports to collector exactly once. If the same pair is observed
+// more than once during the round, the last observation wins.
+func (a *AsyncInstrumentState) Run(ctx context.Context, collector AsyncCollector) error {
+	a.seen = map[asyncStateKey]*asyncObservation{}
+	a.order = a.order[:0]
+
+	dedup := &dedupingCollector{state: a}
+
+	switch runner := a.runner.(type) {
+	case AsyncSingleRunner:
+		runner.Run(ctx, dedup)
+	case AsyncBatchRunner:
+		runner.Run(ctx, dedup)
+	default:
+		return fmt.Errorf("metric: AsyncRunner %T is neither an AsyncSingleRunner nor an AsyncBatchRunner", runner)
+	}
+
+	for _, key := range a.order {
+		obs := a.seen[key]
+		collector.CollectAsync(obs.descriptor, obs.labels, obs.number)
+	}
+	return nil
+}
+
+// dedupingCollector is the AsyncCollector passed to the wrapped
+// runner's callback; it records into AsyncInstrumentState.seen
+// instead of forwarding immediately.
+type dedupingCollector struct {
+	state *AsyncInstrumentState
+}
+
+var _ AsyncCollector = (*dedupingCollector)(nil)
+
+func (d *dedupingCollector) CollectAsync(descriptor *metric.Descriptor, labels *attribute.Set, number core.Number) {
+	key := asyncStateKey{descriptor: descriptor, labels: labels.Equivalent()}
+	if _, ok := d.state.seen[key]; !ok {
+		d.state.order = append(d.state.order, key)
+	}
+	d.state.seen[key] = &asyncObservation{
+		descriptor: descriptor,
+		labels:     labels,
+		number:     number,
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/api/attribute"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+)
+
+type fakeSingleRunner struct {
+	fn func(ctx context.Context, collector AsyncCollector)
+}
+
+func (fakeSingleRunner) AnyRunner() {}
+func (r fakeSingleRunner) Run(ctx context.Context, collector AsyncCollector) {
+	r.fn(ctx, collector)
+}
+
+var _ AsyncSingleRunner = fakeSingleRunner{}
+
+type recordingCollector struct {
+	calls []asyncObservation
+}
+
+func (c *recordingCollector) CollectAsync(descriptor *metric.Descriptor, labels *attribute.Set, number core.Number) {
+	c.calls = append(c.calls, asyncObservation{descriptor: descriptor, labels: labels, number: number})
+}
+
+var _ AsyncCollector = (*recordingCollector)(nil)
+
+func TestAsyncInstrumentStateDedupesLastObservationWins(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet(core.Key("a").String("1"))
+
+	runner := fakeSingleRunner{fn: func(ctx context.Context, collector AsyncCollector) {
+		collector.CollectAsync(desc, &labels, core.NewInt64Number(1))
+		collector.CollectAsync(desc, &labels, core.NewInt64Number(2))
+	}}
+
+	state := NewAsyncInstrumentState(runner)
+	out := &recordingCollector{}
+	if err := state.Run(context.Background(), out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(out.calls) != 1 {
+		t.Fatalf("expected exactly one de-duplicated observation, got %d", len(out.calls))
+	}
+	if out.calls[0].number != core.NewInt64Number(2) {
+		t.Fatalf("expected the last observation (2) to win, got %v", out.calls[0].number)
+	}
+}
+
+func TestAsyncInstrumentStateKeepsDistinctLabelSets(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labelsA := attribute.NewSet(core.Key("a").String("1"))
+	labelsB := attribute.NewSet(core.Key("a").String("2"))
+
+	runner := fakeSingleRunner{fn: func(ctx context.Context, collector AsyncCollector) {
+		collector.CollectAsync(desc, &labelsA, core.NewInt64Number(1))
+		collector.CollectAsync(desc, &labelsB, core.NewInt64Number(2))
+	}}
+
+	state := NewAsyncInstrumentState(runner)
+	out := &recordingCollector{}
+	if err := state.Run(context.Background(), out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(out.calls) != 2 {
+		t.Fatalf("expected two distinct observations, got %d", len(out.calls))
+	}
+}
+
+func TestAsyncInstrumentStateResetsBetweenRounds(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet(core.Key("a").String("1"))
+	calls := 0
+
+	runner := fakeSingleRunner{fn: func(ctx context.Context, collector AsyncCollector) {
+		calls++
+		collector.CollectAsync(desc, &labels, core.NewInt64Number(int64(calls)))
+	}}
+
+	state := NewAsyncInstrumentState(runner)
+
+	out1 := &recordingCollector{}
+	if err := state.Run(context.Background(), out1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out2 := &recordingCollector{}
+	if err := state.Run(context.Background(), out2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(out1.calls) != 1 || len(out2.calls) != 1 {
+		t.Fatalf("expected one observation per round, got %d and %d", len(out1.calls), len(out2.calls))
+	}
+	if out1.calls[0].number == out2.calls[0].number {
+		t.Fatalf("expected state from round 1 not to leak into round 2")
+	}
+}
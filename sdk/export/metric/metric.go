@@ -16,10 +16,15 @@ package metric // import "go.opentelemetry.io/otel/sdk/export/metric"
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"sync/atomic"
 
+	"go.opentelemetry.io/otel/api/attribute"
 	"go.opentelemetry.io/otel/api/core"
 	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/resource"
 )
 
 const (
@@ -84,9 +89,15 @@ type Batcher interface {
 	// disable metrics with active records.
 	AggregationSelector
 
+	// ExportKindSelector is responsible for selecting the kind of
+	// export-data temporality (delta, cumulative, or pass-through)
+	// to use for a metric instrument.
+	ExportKindSelector
+
 	// Process is called by the SDK once per internal record,
 	// passing the export Record (a Descriptor, the corresponding
-	// Labels, and the checkpointed Aggregator).
+	// Labels, the Resource that produced it, and the checkpointed
+	// Aggregator).
 	//
 	// The Context argument originates from the controller that
 	// orchestrates collection.
@@ -107,17 +118,162 @@ type Batcher interface {
 // AggregationSelector supports selecting the kind of Aggregator to
 // use at runtime for a specific metric instrument.
 type AggregationSelector interface {
-	// AggregatorFor returns the kind of aggregator suited to the
-	// requested export.  Returning `nil` indicates to ignore this
-	// metric instrument.  This must return a consistent type to
-	// avoid confusion in later stages of the metrics export
-	// process, i.e., when Merging multiple aggregators for a
-	// specific instrument.
+	// AggregatorFor fills in aggPtrs with new Aggregators suited to
+	// the requested export.  This supports binding a single
+	// instrument to multiple aggregators, e.g., to drive more than
+	// one export pipeline from a single set of measurements.
+	//
+	// If the length of aggPtrs is one, this behaves identically to
+	// the single-aggregator case.  A leftover *aggPtrs[i] of nil
+	// indicates to ignore this metric instrument for that pipeline.
+	// This must return a consistent type per-slot to avoid confusion
+	// in later stages of the metrics export process, i.e., when
+	// Merging multiple aggregators for a specific instrument.
 	//
 	// Note: This is context-free because the aggregator should
 	// not relate to the incoming context.  This call should not
 	// block.
-	AggregatorFor(*metric.Descriptor) Aggregator
+	AggregatorFor(descriptor *metric.Descriptor, aggPtrs ...*Aggregator)
+}
+
+// NewAggregatorSlice allocates len(aggPtrs) Aggregators of a single
+// concrete type in one backing array, and fills each *aggPtrs[i]
+// with an interface value pointing at its element. Only the type of
+// sample is used: it may be a pointer to a struct (as returned by the
+// usual constructors) or a struct value, and each new element is the
+// zero value of that struct type, not a copy of sample.
+// NewAggregatorSlice panics if a pointer to that struct type does not
+// implement Aggregator.
+//
+// This is the allocation pattern an AggregationSelector should use
+// to implement AggregatorFor, so that binding an instrument to
+// several pipelines (len(aggPtrs) > 1) costs a single allocation
+// instead of one per pipeline:
+//
+//	func (s *mySelector) AggregatorFor(_ *metric.Descriptor, aggPtrs ...*export.Aggregator) {
+//	  export.NewAggregatorSlice(sum.New(), aggPtrs)
+//	}
+func NewAggregatorSlice(sample Aggregator, aggPtrs []*Aggregator) {
+	if len(aggPtrs) == 0 {
+		return
+	}
+	elemType := reflect.TypeOf(sample)
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	array := reflect.New(reflect.ArrayOf(len(aggPtrs), elemType)).Elem()
+	for i := range aggPtrs {
+		elem, ok := array.Index(i).Addr().Interface().(Aggregator)
+		if !ok {
+			panic(fmt.Sprintf("metric: *%s does not implement Aggregator", elemType))
+		}
+		*aggPtrs[i] = elem
+	}
+}
+
+// ExportKind indicates the temporality of an exported Aggregator
+// state, i.e., whether its value is a delta since the last
+// collection, a cumulative value since the start of the process, or
+// unspecified, meaning the Aggregator's checkpoint should be used
+// as-is (this is appropriate for LastValue aggregations, which are
+// neither deltas nor cumulative sums).
+type ExportKind int
+
+const (
+	// PassThroughExportKind indicates that the Aggregator's
+	// checkpoint should be used as the exported point, without
+	// further conversion.  This is the only valid choice for
+	// aggregations that are not considered additive, e.g.,
+	// LastValue.
+	PassThroughExportKind ExportKind = iota
+
+	// CumulativeExportKind indicates that the exporter expects a
+	// cumulative sum of all values since the start of the process,
+	// computed by merging the current delta checkpoint into the
+	// accumulated total.
+	CumulativeExportKind
+
+	// DeltaExportKind indicates that the exporter expects the
+	// checkpointed Aggregator's value as-computed, i.e., the change
+	// since the last collection.
+	DeltaExportKind
+)
+
+// MatchesAggregationKind returns whether an ExportKind is valid for
+// a given aggregation.Kind.  PassThroughExportKind is the only valid
+// choice for LastValue aggregations; the other aggregation kinds
+// support either Cumulative or Delta.
+func (ek ExportKind) MatchesAggregationKind(kind aggregation.Kind) bool {
+	if kind == aggregation.LastValueKind {
+		return ek == PassThroughExportKind
+	}
+	return ek == CumulativeExportKind || ek == DeltaExportKind
+}
+
+// ExportKindSelector supports selecting the kind of exporter-level
+// aggregation temporality (delta or cumulative) to use for a metric
+// instrument, as requested by the Exporter bound to a Batcher.
+type ExportKindSelector interface {
+	// ExportKindFor should return the correct ExportKind for the
+	// given metric Descriptor and Aggregator Kind.  Batchers are
+	// permitted to use the Descriptor to make this decision, e.g.,
+	// by InstrumentKind.
+	ExportKindFor(descriptor *metric.Descriptor, kind aggregation.Kind) ExportKind
+}
+
+type (
+	statelessExportKindSelector  struct{}
+	cumulativeExportKindSelector struct{}
+	deltaExportKindSelector      struct{}
+)
+
+// NewStatelessSelector returns an ExportKindSelector that always
+// returns the DeltaExportKind, except for a LastValue aggregation,
+// which is always PassThroughExportKind.  This is the best choice
+// for an exporter that reports to a stateless, delta-oriented
+// backend (e.g., StatsD) and can be used with any stateless
+// Batcher, since no state is accumulated in the SDK.
+func NewStatelessSelector() ExportKindSelector {
+	return statelessExportKindSelector{}
+}
+
+// NewCumulativeSelector returns an ExportKindSelector that always
+// returns CumulativeExportKind, except for a LastValue aggregation,
+// which is always PassThroughExportKind.  This requires a stateful
+// Batcher, since the cumulative value is computed by merging each
+// delta checkpoint into a running total; see StatefulMerger, which
+// implements that merge for a Batcher's Process method.
+func NewCumulativeSelector() ExportKindSelector {
+	return cumulativeExportKindSelector{}
+}
+
+// NewDeltaSelector returns an ExportKindSelector that always returns
+// DeltaExportKind, except for a LastValue aggregation, which is
+// always PassThroughExportKind.  This is appropriate for exporters
+// that natively understand and prefer delta-temporality data.
+func NewDeltaSelector() ExportKindSelector {
+	return deltaExportKindSelector{}
+}
+
+func (statelessExportKindSelector) ExportKindFor(_ *metric.Descriptor, kind aggregation.Kind) ExportKind {
+	if kind == aggregation.LastValueKind {
+		return PassThroughExportKind
+	}
+	return DeltaExportKind
+}
+
+func (cumulativeExportKindSelector) ExportKindFor(_ *metric.Descriptor, kind aggregation.Kind) ExportKind {
+	if kind == aggregation.LastValueKind {
+		return PassThroughExportKind
+	}
+	return CumulativeExportKind
+}
+
+func (deltaExportKindSelector) ExportKindFor(_ *metric.Descriptor, kind aggregation.Kind) ExportKind {
+	if kind == aggregation.LastValueKind {
+		return PassThroughExportKind
+	}
+	return DeltaExportKind
 }
 
 // Aggregator implements a specific aggregation behavior, e.g., a
@@ -169,6 +325,68 @@ type Aggregator interface {
 	// Merge() is called in a single-threaded context, no locking
 	// is required.
 	Merge(Aggregator, *metric.Descriptor) error
+
+	// SynchronizedMove atomically saves the current value and
+	// resets the current state to the empty state.  Unlike
+	// Checkpoint, this may be called concurrently with Update,
+	// which makes it suitable for use with asynchronous (observer)
+	// instruments, where an Update arriving via a re-entrant
+	// callback could otherwise race with a collection in progress.
+	//
+	// For sum-like observer semantics, the destination Aggregator
+	// receives the moved state and this Aggregator is reset to
+	// the empty state, as with Checkpoint.  For gauge-like (last
+	// value) observer semantics, the implementation is expected to
+	// leave its last-set value in place rather than resetting it,
+	// so repeated collections continue to report the most recent
+	// observation.
+	SynchronizedMove(destination Aggregator, descriptor *metric.Descriptor) error
+}
+
+// AsyncCollector is an interface used between the SDK and an
+// asynchronous Runner.  The SDK calls CollectAsync once for every
+// observation an asynchronous instrument's callback reports during a
+// single collection pass.
+type AsyncCollector interface {
+	// CollectAsync passes a single observed measurement along with
+	// the Descriptor and the label Set under which it was recorded.
+	CollectAsync(descriptor *metric.Descriptor, labels *attribute.Set, number core.Number)
+}
+
+// AsyncRunner is either an AsyncSingleRunner or an AsyncBatchRunner,
+// depending on whether the callback it wraps reports values for a
+// single instrument or for a batch of instruments sharing one set of
+// labels.  The two variants have the same method set, so a
+// type-assertion cannot tell them apart; both are invoked by calling
+// Run with an AsyncCollector.
+type AsyncRunner interface {
+	// AnyRunner has no functional use other than to make this a
+	// non-empty interface.
+	AnyRunner()
+}
+
+// AsyncSingleRunner is an AsyncRunner that invokes a single
+// instrument's callback, reporting at most one observation per
+// distinct label set during a collection.
+type AsyncSingleRunner interface {
+	AsyncRunner
+
+	// Run invokes the registered callback for a single asynchronous
+	// instrument, passing the AsyncCollector the callback uses to
+	// report its observation.
+	Run(ctx context.Context, collector AsyncCollector)
+}
+
+// AsyncBatchRunner is an AsyncRunner that invokes a single callback
+// that may report observations for multiple asynchronous instruments
+// sharing a common set of labels in one invocation.
+type AsyncBatchRunner interface {
+	AsyncRunner
+
+	// Run invokes the registered batch callback, which may report
+	// observations for any number of asynchronous instruments
+	// through collector.
+	Run(ctx context.Context, collector AsyncCollector)
 }
 
 // Exporter handles presentation of the checkpoint of aggregate
@@ -186,118 +404,17 @@ type Exporter interface {
 	Export(context.Context, CheckpointSet) error
 }
 
-// LabelStorage provides an access to the ordered labels.
-type LabelStorage interface {
-	// NumLabels returns a number of labels in the storage.
-	NumLabels() int
-	// GetLabels gets a label from a passed index.
-	GetLabel(int) core.KeyValue
-}
-
-// LabelSlice implements LabelStorage in terms of a slice.
-type LabelSlice []core.KeyValue
-
-var _ LabelStorage = LabelSlice{}
-
-// NumLabels is a part of LabelStorage implementation.
-func (s LabelSlice) NumLabels() int {
-	return len(s)
-}
-
-// GetLabel is a part of LabelStorage implementation.
-func (s LabelSlice) GetLabel(idx int) core.KeyValue {
-	return s[idx]
-}
-
-// Iter returns an iterator going over the slice.
-func (s LabelSlice) Iter() LabelIterator {
-	return NewLabelIterator(s)
-}
-
-// LabelIterator allows iterating over an ordered set of labels. The
-// typical use of the iterator is as follows:
-//
-//     iter := export.NewLabelIterator(getStorage())
-//     for iter.Next() {
-//       label := iter.Label()
-//       // or, if we need an index:
-//       // idx, label := iter.IndexedLabel()
-//       // do something with label
-//     }
-type LabelIterator struct {
-	storage LabelStorage
-	idx     int
-}
-
-// NewLabelIterator creates an iterator going over a passed storage.
-func NewLabelIterator(storage LabelStorage) LabelIterator {
-	return LabelIterator{
-		storage: storage,
-		idx:     -1,
-	}
-}
-
-// Next moves the iterator to the next label. Returns false if there
-// are no more labels.
-func (i *LabelIterator) Next() bool {
-	i.idx++
-	return i.idx < i.Len()
-}
-
-// Label returns current label. Must be called only after Next returns
-// true.
-func (i *LabelIterator) Label() core.KeyValue {
-	return i.storage.GetLabel(i.idx)
-}
-
-// IndexedLabel returns current index and label. Must be called only
-// after Next returns true.
-func (i *LabelIterator) IndexedLabel() (int, core.KeyValue) {
-	return i.idx, i.Label()
-}
-
-// Len returns a number of labels in the iterator's label storage.
-func (i *LabelIterator) Len() int {
-	return i.storage.NumLabels()
-}
-
-// Convenience function that creates a slice of labels from the passed
-// iterator. The iterator is set up to start from the beginning before
-// creating the slice.
-func IteratorToSlice(iter LabelIterator) []core.KeyValue {
-	l := iter.Len()
-	if l == 0 {
-		return nil
-	}
-	iter.idx = -1
-	slice := make([]core.KeyValue, 0, l)
-	for iter.Next() {
-		slice = append(slice, iter.Label())
-	}
-	return slice
-}
-
 // LabelEncoder enables an optimization for export pipelines that use
 // text to encode their label sets.
 //
 // This interface allows configuring the encoder used in the Batcher
 // so that by the time the exporter is called, the same encoding may
 // be used.
-type LabelEncoder interface {
-	// Encode is called (concurrently) in instrumentation context.
-	//
-	// The expectation is that when setting up an export pipeline
-	// both the batcher and the exporter will use the same label
-	// encoder to avoid the duplicate computation of the encoded
-	// labels in the export path.
-	Encode(LabelIterator) string
-
-	// ID should return a unique positive number associated with
-	// the label encoder. Stateless label encoders could return
-	// the same number regardless of an instance, stateful label
-	// encoders should return a number depending on their state.
-	ID() int64
-}
+//
+// LabelEncoder is an alias of attribute.Encoder, kept here for
+// source compatibility with exporters that were written against the
+// prior, export/metric-local definition.
+type LabelEncoder = attribute.Encoder
 
 // CheckpointSet allows a controller to access a complete checkpoint of
 // aggregated metrics from the Batcher.  This is passed to the
@@ -319,59 +436,32 @@ type CheckpointSet interface {
 // and label set.
 type Record struct {
 	descriptor *metric.Descriptor
-	labels     Labels
+	labels     *attribute.Set
+	resource   *resource.Resource
 	aggregator Aggregator
 }
 
-// Labels stores complete information about a computed label set,
-// including the labels in an appropriate order (as defined by the
-// Batcher).  If the batcher does not re-order labels, they are
-// presented in sorted order by the SDK.
-type Labels interface {
-	Iter() LabelIterator
-	Encoded(LabelEncoder) string
-}
-
-type labels struct {
-	encoderID int64
-	encoded   string
-	slice     LabelSlice
-}
-
-var _ Labels = &labels{}
-
-// NewSimpleLabels builds a Labels object, consisting of an ordered
-// set of labels in a provided slice and a unique encoded
-// representation generated by the passed encoder.
-func NewSimpleLabels(encoder LabelEncoder, kvs ...core.KeyValue) Labels {
-	l := &labels{
-		encoderID: encoder.ID(),
-		slice:     kvs,
-	}
-	l.encoded = encoder.Encode(l.Iter())
-	return l
-}
-
-// Iter is a part of an implementation of the Labels interface.
-func (l *labels) Iter() LabelIterator {
-	return l.slice.Iter()
-}
-
-// Encoded is a part of an implementation of the Labels interface.
-func (l *labels) Encoded(encoder LabelEncoder) string {
-	if l.encoderID == encoder.ID() {
-		return l.encoded
-	}
-	return encoder.Encode(l.Iter())
-}
-
 // NewRecord allows Batcher implementations to construct export
 // records.  The Descriptor, Labels, and Aggregator represent
 // aggregate metric events received over a single collection period.
-func NewRecord(descriptor *metric.Descriptor, labels Labels, aggregator Aggregator) Record {
+//
+// Deprecated: use NewRecordWithResource instead, which also
+// associates the Resource that produced the aggregation.  This
+// constructor remains for Batchers that do not have a Resource
+// available and attaches no Resource to the Record.
+func NewRecord(descriptor *metric.Descriptor, labels *attribute.Set, aggregator Aggregator) Record {
+	return NewRecordWithResource(descriptor, labels, nil, aggregator)
+}
+
+// NewRecordWithResource allows Batcher implementations to construct
+// export records.  The Descriptor, Labels, Resource, and Aggregator
+// represent aggregate metric events received over a single
+// collection period.
+func NewRecordWithResource(descriptor *metric.Descriptor, labels *attribute.Set, res *resource.Resource, aggregator Aggregator) Record {
 	return Record{
 		descriptor: descriptor,
 		labels:     labels,
+		resource:   res,
 		aggregator: aggregator,
 	}
 }
@@ -387,8 +477,17 @@ func (r Record) Descriptor() *metric.Descriptor {
 	return r.descriptor
 }
 
-// Labels describes the labels associated with the instrument and the
-// aggregated data.
-func (r Record) Labels() Labels {
+// Labels describes the de-duplicated, sorted label Set associated
+// with the instrument and the aggregated data. The SDK keys its
+// internal record map on Labels.Equivalent(), so lookups on the hot
+// path never require encoding labels to a string.
+func (r Record) Labels() *attribute.Set {
 	return r.labels
 }
+
+// Resource contains the entity that produced this aggregation. This
+// may be nil if the Batcher that constructed the Record did not have
+// a Resource available, e.g., through NewRecord.
+func (r Record) Resource() *resource.Resource {
+	return r.resource
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp // import "go.opentelemetry.io/otel/sdk/export/metric/otlp"
+
+import (
+	"context"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+)
+
+// driver abstracts over the two OTLP transports Exporter supports
+// (gRPC and HTTP/protobuf), so that Export's transform, batching,
+// and retry logic is shared across both.
+type driver interface {
+	// export sends req to the collector, returning an error that
+	// retryable can classify as transient or permanent.
+	export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) error
+
+	// retryable reports whether err, as returned from export,
+	// represents a transient failure worth retrying (e.g., the
+	// collector was temporarily unavailable or overloaded), as
+	// opposed to a permanent failure (e.g., an invalid request or
+	// an authentication failure) that will not succeed no matter
+	// how many times it is retried.
+	retryable(err error) bool
+
+	// shutdown releases any resources held by the driver, e.g., a
+	// gRPC connection or an HTTP client's idle connections.
+	shutdown(ctx context.Context) error
+}
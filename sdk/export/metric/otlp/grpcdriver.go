@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp // import "go.opentelemetry.io/otel/sdk/export/metric/otlp"
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcDriver implements driver over a gRPC connection to the
+// collector's MetricsService.
+type grpcDriver struct {
+	cfg config
+
+	mu   sync.Mutex
+	conn *grpc.ClientConn
+	msc  colmetricpb.MetricsServiceClient
+}
+
+var _ driver = (*grpcDriver)(nil)
+
+// retryableGRPCCodes are the gRPC status codes that indicate the
+// failure is transient and the call is safe to retry unchanged.
+// Everything else (InvalidArgument, Unauthenticated,
+// PermissionDenied, NotFound, etc.) indicates a permanent failure
+// that retrying cannot fix.
+var retryableGRPCCodes = map[codes.Code]bool{
+	codes.Unavailable:       true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+	codes.Aborted:           true,
+}
+
+func newGRPCDriver(ctx context.Context, cfg config) (*grpcDriver, error) {
+	dialOpts := []grpc.DialOption{grpc.WithBlock()}
+	if cfg.clientCredentials != nil {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(cfg.clientCredentials))
+	} else if cfg.insecure {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	}
+	if cfg.reconnectionPeriod != 0 {
+		backoffConfig := backoff.DefaultConfig
+		backoffConfig.BaseDelay = cfg.reconnectionPeriod
+		dialOpts = append(dialOpts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoffConfig,
+			// gRPC's own default, which ConnectParams would otherwise
+			// replace with zero.
+			MinConnectTimeout: 20 * time.Second,
+		}))
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, cfg.dialTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(dialCtx, cfg.address, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("otlp: failed to connect to collector at %s: %w", cfg.address, err)
+	}
+
+	return &grpcDriver{
+		cfg:  cfg,
+		conn: conn,
+		msc:  colmetricpb.NewMetricsServiceClient(conn),
+	}, nil
+}
+
+func (d *grpcDriver) export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) error {
+	if len(d.cfg.headers) > 0 {
+		ctx = attachHeaders(ctx, d.cfg.headers)
+	}
+
+	d.mu.Lock()
+	client := d.msc
+	d.mu.Unlock()
+
+	_, err := client.Export(ctx, req)
+	return err
+}
+
+func (d *grpcDriver) retryable(err error) bool {
+	return retryableGRPCCodes[status.Code(err)]
+}
+
+func (d *grpcDriver) shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil {
+		return nil
+	}
+	return d.conn.Close()
+}
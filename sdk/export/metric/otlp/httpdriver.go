@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp // import "go.opentelemetry.io/otel/sdk/export/metric/otlp"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpDriver implements driver by POSTing a protobuf-encoded
+// ExportMetricsServiceRequest to the collector's OTLP/HTTP metrics
+// endpoint, as an alternative to the gRPC transport.
+type httpDriver struct {
+	cfg    config
+	client *http.Client
+	url    string
+}
+
+var _ driver = (*httpDriver)(nil)
+
+func newHTTPDriver(cfg config) *httpDriver {
+	scheme := "https"
+	if cfg.insecure {
+		scheme = "http"
+	}
+	return &httpDriver{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.dialTimeout},
+		url:    fmt.Sprintf("%s://%s%s", scheme, cfg.address, cfg.httpPath),
+	}
+}
+
+// httpStatusError reports a non-2xx response from the collector, so
+// that retryable can classify it without re-parsing the response.
+type httpStatusError struct {
+	statusCode int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("otlp: collector responded with HTTP status %d", e.statusCode)
+}
+
+func (d *httpDriver) export(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("otlp: failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("otlp: failed to build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range d.cfg.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := d.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return &httpStatusError{statusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// retryableHTTPStatus are the HTTP status codes that indicate the
+// failure is transient and the request is safe to retry unchanged.
+// Everything else (400, 401, 403, 404, ...) indicates a permanent
+// failure that retrying cannot fix.
+var retryableHTTPStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+func (d *httpDriver) retryable(err error) bool {
+	statusErr, ok := err.(*httpStatusError)
+	if !ok {
+		// Transport-level errors (connection refused, timeout, DNS
+		// failure, ...) are treated as transient.
+		return true
+	}
+	return retryableHTTPStatus[statusErr.statusCode]
+}
+
+func (d *httpDriver) shutdown(ctx context.Context) error {
+	d.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp // import "go.opentelemetry.io/otel/sdk/export/metric/otlp"
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+)
+
+// RetrySettings configures the backoff behavior used when an Export
+// call to the collector fails.
+type RetrySettings struct {
+	// MaxAttempts is the number of retries attempted after an
+	// initial failed Export call.
+	MaxAttempts int
+
+	// InitialInterval is the backoff delay before the first retry.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the exponentially increasing backoff delay.
+	MaxInterval time.Duration
+}
+
+// protocol selects the wire transport Exporter uses to reach the
+// collector.
+type protocol int
+
+const (
+	protocolGRPC protocol = iota
+	protocolHTTP
+)
+
+type config struct {
+	protocol           protocol
+	address            string
+	httpPath           string
+	insecure           bool
+	clientCredentials  credentials.TransportCredentials
+	headers            map[string]string
+	reconnectionPeriod time.Duration
+	dialTimeout        time.Duration
+	retrySettings      RetrySettings
+	exportKindSelector export.ExportKindSelector
+}
+
+// Option configures the Exporter returned by NewExporter.
+type Option func(*config)
+
+func newConfig(opts ...Option) config {
+	cfg := config{
+		protocol: protocolGRPC,
+		address:  "localhost:4317",
+		httpPath: "/v1/metrics",
+		insecure: false,
+		retrySettings: RetrySettings{
+			MaxAttempts:     5,
+			InitialInterval: 300 * time.Millisecond,
+			MaxInterval:     5 * time.Second,
+		},
+		exportKindSelector: export.NewCumulativeSelector(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.dialTimeout == 0 {
+		cfg.dialTimeout = 10 * time.Second
+	}
+	return cfg
+}
+
+// WithAddress sets the collector address the Exporter dials,
+// formatted as "host:port". The default is "localhost:4317".
+func WithAddress(address string) Option {
+	return func(cfg *config) {
+		cfg.address = address
+	}
+}
+
+// WithHTTP selects the HTTP/protobuf transport in place of the
+// default gRPC transport, POSTing a protobuf-encoded
+// ExportMetricsServiceRequest to path (default "/v1/metrics") on the
+// configured address for every Export call.
+func WithHTTP(path string) Option {
+	return func(cfg *config) {
+		cfg.protocol = protocolHTTP
+		if path != "" {
+			cfg.httpPath = path
+		}
+	}
+}
+
+// WithDialTimeout bounds how long NewExporter waits for the initial
+// connection to the collector to be established, independent of
+// WithReconnectionPeriod, which governs steady-state reconnection
+// backoff after the Exporter is already running. The default is 10
+// seconds.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.dialTimeout = timeout
+	}
+}
+
+// WithInsecure disables client transport security for the gRPC
+// connection, equivalent to grpc.WithInsecure. This is mutually
+// exclusive with WithTLSCredentials.
+func WithInsecure() Option {
+	return func(cfg *config) {
+		cfg.insecure = true
+		cfg.clientCredentials = nil
+	}
+}
+
+// WithTLSCredentials configures the gRPC connection to use the
+// provided transport credentials. This is mutually exclusive with
+// WithInsecure.
+func WithTLSCredentials(creds credentials.TransportCredentials) Option {
+	return func(cfg *config) {
+		cfg.clientCredentials = creds
+		cfg.insecure = false
+	}
+}
+
+// WithHeaders sets gRPC metadata headers to be sent with every
+// Export call, e.g., for collector authentication.
+func WithHeaders(headers map[string]string) Option {
+	return func(cfg *config) {
+		cfg.headers = headers
+	}
+}
+
+// WithReconnectionPeriod sets gRPC's minimum connection backoff used
+// when reconnecting after the initial connection is lost. It has no
+// effect on the one-shot timeout NewExporter applies to the initial
+// connection attempt; see WithDialTimeout for that. This option only
+// applies to the gRPC transport.
+func WithReconnectionPeriod(period time.Duration) Option {
+	return func(cfg *config) {
+		cfg.reconnectionPeriod = period
+	}
+}
+
+// WithRetry configures the backoff behavior used when an Export call
+// fails.
+func WithRetry(settings RetrySettings) Option {
+	return func(cfg *config) {
+		cfg.retrySettings = settings
+	}
+}
+
+// WithExportKindSelector sets the ExportKindSelector used to decide
+// whether each instrument's data points are reported with Delta or
+// Cumulative AggregationTemporality. The default is
+// export.NewCumulativeSelector().
+func WithExportKindSelector(selector export.ExportKindSelector) Option {
+	return func(cfg *config) {
+		cfg.exportKindSelector = selector
+	}
+}
+
+func attachHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return metadata.NewOutgoingContext(ctx, metadata.New(headers))
+}
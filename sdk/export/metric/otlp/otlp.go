@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp implements a metric exporter that translates SDK
+// CheckpointSets into the OTLP metrics protocol and ships them to a
+// collector, over either gRPC or HTTP/protobuf.
+package otlp // import "go.opentelemetry.io/otel/sdk/export/metric/otlp"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+)
+
+// Exporter satisfies the export.Exporter interface, translating each
+// collection's CheckpointSet into an OTLP ExportMetricsServiceRequest
+// and shipping it to a collector over the configured transport.
+type Exporter struct {
+	cfg    config
+	driver driver
+}
+
+var _ export.Exporter = (*Exporter)(nil)
+
+// NewExporter constructs a new Exporter and establishes its
+// connection to the configured collector address, using gRPC by
+// default or HTTP/protobuf when WithHTTP is passed. The context is
+// used only while the connection is established; it is not retained.
+func NewExporter(ctx context.Context, opts ...Option) (*Exporter, error) {
+	cfg := newConfig(opts...)
+
+	var d driver
+	switch cfg.protocol {
+	case protocolHTTP:
+		d = newHTTPDriver(cfg)
+	default:
+		gd, err := newGRPCDriver(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		d = gd
+	}
+
+	return &Exporter{
+		cfg:    cfg,
+		driver: d,
+	}, nil
+}
+
+// Export transforms the CheckpointSet into OTLP ResourceMetrics,
+// batched by (Resource, InstrumentationLibrary), and sends them to
+// the collector, retrying transient failures with backoff according
+// to the configured RetrySettings.
+func (e *Exporter) Export(ctx context.Context, cps export.CheckpointSet) error {
+	rms, err := checkpointSetToResourceMetrics(cps, e.cfg.exportKindSelector)
+	if err != nil {
+		return fmt.Errorf("otlp: failed to transform checkpoint set: %w", err)
+	}
+	if len(rms) == 0 {
+		return nil
+	}
+
+	req := &colmetricpb.ExportMetricsServiceRequest{
+		ResourceMetrics: rms,
+	}
+
+	return e.sendWithRetry(ctx, req)
+}
+
+func (e *Exporter) sendWithRetry(ctx context.Context, req *colmetricpb.ExportMetricsServiceRequest) error {
+	var lastErr error
+	backoff := e.cfg.retrySettings.InitialInterval
+
+	for attempt := 0; attempt <= e.cfg.retrySettings.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > e.cfg.retrySettings.MaxInterval {
+				backoff = e.cfg.retrySettings.MaxInterval
+			}
+		}
+
+		lastErr = e.driver.export(ctx, req)
+		if lastErr == nil {
+			return nil
+		}
+		if !e.driver.retryable(lastErr) {
+			return fmt.Errorf("otlp: export failed with a permanent error: %w", lastErr)
+		}
+	}
+	return fmt.Errorf("otlp: export failed after %d attempts: %w", e.cfg.retrySettings.MaxAttempts+1, lastErr)
+}
+
+// Shutdown releases the resources held by the Exporter's transport.
+// No further Export calls should be made after Shutdown returns.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	return e.driver.shutdown(ctx)
+}
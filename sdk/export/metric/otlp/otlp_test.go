@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+)
+
+type fakeDriver struct {
+	exportErrs     []error
+	exportCalls    int
+	retryableFn    func(error) bool
+	shutdownCalled bool
+}
+
+// export returns exportErrs[exportCalls] (clamped to the last entry
+// once exhausted) and always counts the call, so exportCalls reflects
+// the total number of times export was invoked regardless of how many
+// distinct errors the test supplied.
+func (d *fakeDriver) export(context.Context, *colmetricpb.ExportMetricsServiceRequest) error {
+	idx := d.exportCalls
+	if idx >= len(d.exportErrs) {
+		idx = len(d.exportErrs) - 1
+	}
+	err := d.exportErrs[idx]
+	d.exportCalls++
+	return err
+}
+
+func (d *fakeDriver) retryable(err error) bool {
+	return d.retryableFn(err)
+}
+
+func (d *fakeDriver) shutdown(context.Context) error {
+	d.shutdownCalled = true
+	return nil
+}
+
+var _ driver = (*fakeDriver)(nil)
+
+func testRetrySettings() RetrySettings {
+	return RetrySettings{
+		MaxAttempts:     3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+	}
+}
+
+func TestSendWithRetrySucceedsAfterTransientErrors(t *testing.T) {
+	transient := errors.New("unavailable")
+	d := &fakeDriver{
+		exportErrs:  []error{transient, transient, nil},
+		retryableFn: func(error) bool { return true },
+	}
+	e := &Exporter{cfg: config{retrySettings: testRetrySettings()}, driver: d}
+
+	err := e.sendWithRetry(context.Background(), &colmetricpb.ExportMetricsServiceRequest{})
+	if err != nil {
+		t.Fatalf("expected eventual success, got error: %v", err)
+	}
+	if d.exportCalls != len(d.exportErrs) {
+		t.Fatalf("expected %d total attempts before success, got %d", len(d.exportErrs), d.exportCalls)
+	}
+}
+
+func TestSendWithRetryStopsOnPermanentError(t *testing.T) {
+	permanent := errors.New("invalid argument")
+	d := &fakeDriver{
+		exportErrs:  []error{permanent},
+		retryableFn: func(error) bool { return false },
+	}
+	e := &Exporter{cfg: config{retrySettings: testRetrySettings()}, driver: d}
+
+	err := e.sendWithRetry(context.Background(), &colmetricpb.ExportMetricsServiceRequest{})
+	if err == nil {
+		t.Fatalf("expected a permanent error to be returned immediately")
+	}
+	if d.exportCalls != 1 {
+		t.Fatalf("expected a single attempt and no retries for a permanent error, got %d calls", d.exportCalls)
+	}
+}
+
+func TestSendWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	transient := errors.New("unavailable")
+	d := &fakeDriver{
+		exportErrs:  []error{transient},
+		retryableFn: func(error) bool { return true },
+	}
+	e := &Exporter{cfg: config{retrySettings: testRetrySettings()}, driver: d}
+
+	err := e.sendWithRetry(context.Background(), &colmetricpb.ExportMetricsServiceRequest{})
+	if err == nil {
+		t.Fatalf("expected an error once retries are exhausted")
+	}
+	wantCalls := e.cfg.retrySettings.MaxAttempts + 1
+	if d.exportCalls != wantCalls {
+		t.Fatalf("expected %d total attempts (the initial attempt plus MaxAttempts retries), got %d", wantCalls, d.exportCalls)
+	}
+}
+
+func TestSendWithRetryRespectsContextCancellation(t *testing.T) {
+	transient := errors.New("unavailable")
+	d := &fakeDriver{
+		exportErrs:  []error{transient},
+		retryableFn: func(error) bool { return true },
+	}
+	e := &Exporter{cfg: config{retrySettings: testRetrySettings()}, driver: d}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := e.sendWithRetry(ctx, &colmetricpb.ExportMetricsServiceRequest{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestShutdownDelegatesToDriver(t *testing.T) {
+	d := &fakeDriver{}
+	e := &Exporter{driver: d}
+
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !d.shutdownCalled {
+		t.Fatalf("expected Shutdown to delegate to the driver")
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp // import "go.opentelemetry.io/otel/sdk/export/metric/otlp"
+
+import (
+	"fmt"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+
+	"go.opentelemetry.io/otel/api/attribute"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// pipelineKey groups Records that belong in the same
+// InstrumentationLibraryMetrics, i.e., that share a Resource and an
+// instrumentation library name. Resources are grouped by
+// pointer identity: a Batcher attaches one shared *resource.Resource
+// to every Record it produces for a given MeterProvider, so pointer
+// equality is sufficient and avoids re-encoding the Resource for
+// every Record.
+type pipelineKey struct {
+	res         *resource.Resource
+	libraryName string
+}
+
+type pipelineGroup struct {
+	res     *resource.Resource
+	library string
+	metrics map[string]*metricpb.Metric
+	order   []string
+}
+
+// checkpointSetToResourceMetrics transforms a complete CheckpointSet
+// into OTLP ResourceMetrics, batched by (Resource, instrumentation
+// library) to minimize the number of proto messages allocated.
+func checkpointSetToResourceMetrics(cps export.CheckpointSet, ekSelector export.ExportKindSelector) ([]*metricpb.ResourceMetrics, error) {
+	groups := map[pipelineKey]*pipelineGroup{}
+	var order []pipelineKey
+
+	err := cps.ForEach(func(record export.Record) error {
+		desc := record.Descriptor()
+		agg, ok := record.Aggregator().(aggregation.Aggregation)
+		if !ok {
+			return fmt.Errorf("otlp: aggregator for %q does not implement aggregation.Aggregation", desc.Name())
+		}
+
+		key := pipelineKey{res: record.Resource(), libraryName: desc.LibraryName()}
+		group, ok := groups[key]
+		if !ok {
+			group = &pipelineGroup{
+				res:     record.Resource(),
+				library: desc.LibraryName(),
+				metrics: map[string]*metricpb.Metric{},
+			}
+			groups[key] = group
+			order = append(order, key)
+		}
+
+		kind := ekSelector.ExportKindFor(desc, agg.Kind())
+		pb, err := recordToMetric(desc, record.Labels(), agg, kind)
+		if err != nil {
+			return fmt.Errorf("otlp: failed to transform %q: %w", desc.Name(), err)
+		}
+		if existing, ok := group.metrics[desc.Name()]; ok {
+			mergeDataPoints(existing, pb)
+		} else {
+			group.metrics[desc.Name()] = pb
+			group.order = append(group.order, desc.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rms := make([]*metricpb.ResourceMetrics, 0, len(order))
+	for _, key := range order {
+		group := groups[key]
+		metrics := make([]*metricpb.Metric, 0, len(group.order))
+		for _, name := range group.order {
+			metrics = append(metrics, group.metrics[name])
+		}
+		rms = append(rms, &metricpb.ResourceMetrics{
+			Resource: resourceToPB(group.res),
+			InstrumentationLibraryMetrics: []*metricpb.InstrumentationLibraryMetrics{
+				{
+					InstrumentationLibrary: &commonpb.InstrumentationLibrary{
+						Name: group.library,
+					},
+					Metrics: metrics,
+				},
+			},
+		})
+	}
+	return rms, nil
+}
+
+// mergeDataPoints appends src's data points onto dst, used when two
+// Records for the same instrument name arrive with different label
+// sets within one collection.
+func mergeDataPoints(dst, src *metricpb.Metric) {
+	switch d := dst.Data.(type) {
+	case *metricpb.Metric_Sum:
+		d.Sum.DataPoints = append(d.Sum.DataPoints, src.Data.(*metricpb.Metric_Sum).Sum.DataPoints...)
+	case *metricpb.Metric_Gauge:
+		d.Gauge.DataPoints = append(d.Gauge.DataPoints, src.Data.(*metricpb.Metric_Gauge).Gauge.DataPoints...)
+	case *metricpb.Metric_Histogram:
+		d.Histogram.DataPoints = append(d.Histogram.DataPoints, src.Data.(*metricpb.Metric_Histogram).Histogram.DataPoints...)
+	}
+}
+
+func recordToMetric(desc *metric.Descriptor, labels *attribute.Set, agg aggregation.Aggregation, kind export.ExportKind) (*metricpb.Metric, error) {
+	pb := &metricpb.Metric{
+		Name:        desc.Name(),
+		Description: desc.Description(),
+		Unit:        string(desc.Unit()),
+	}
+
+	temporality := toAggregationTemporality(kind)
+	attrs := toAttributes(labels)
+
+	// Histogram and MinMaxSumCount also have a Sum method, so they
+	// are matched before the plain Sum case.
+	switch a := agg.(type) {
+	case aggregation.LastValue:
+		value, _, err := a.LastValue()
+		if err != nil {
+			return nil, err
+		}
+		pb.Data = &metricpb.Metric_Gauge{
+			Gauge: &metricpb.Gauge{
+				DataPoints: []*metricpb.NumberDataPoint{numberDataPoint(attrs, desc, value)},
+			},
+		}
+	case aggregation.Histogram:
+		buckets, err := a.Histogram()
+		if err != nil {
+			return nil, err
+		}
+		sum, err := a.Sum()
+		if err != nil {
+			return nil, err
+		}
+		count, err := a.Count()
+		if err != nil {
+			return nil, err
+		}
+		pb.Data = &metricpb.Metric_Histogram{
+			Histogram: &metricpb.Histogram{
+				AggregationTemporality: temporality,
+				DataPoints: []*metricpb.HistogramDataPoint{
+					{
+						Attributes:     attrs,
+						Count:          uint64(count),
+						Sum:            numberAsFloat64(sum, desc.NumberKind()),
+						BucketCounts:   buckets.Counts,
+						ExplicitBounds: buckets.Boundaries,
+					},
+				},
+			},
+		}
+	case aggregation.MinMaxSumCount:
+		// MinMaxSumCount without an associated Histogram (e.g., a
+		// summary-style aggregator) is exported as its sum, since
+		// OTLP has no direct summary-without-quantiles data point.
+		sum, err := a.Sum()
+		if err != nil {
+			return nil, err
+		}
+		pb.Data = &metricpb.Metric_Sum{
+			Sum: &metricpb.Sum{
+				AggregationTemporality: temporality,
+				IsMonotonic:            false,
+				DataPoints:             []*metricpb.NumberDataPoint{numberDataPoint(attrs, desc, sum)},
+			},
+		}
+	case aggregation.Sum:
+		value, err := a.Sum()
+		if err != nil {
+			return nil, err
+		}
+		pb.Data = &metricpb.Metric_Sum{
+			Sum: &metricpb.Sum{
+				AggregationTemporality: temporality,
+				IsMonotonic:            isMonotonicInstrumentKind(desc.InstrumentKind()),
+				DataPoints:             []*metricpb.NumberDataPoint{numberDataPoint(attrs, desc, value)},
+			},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported aggregation kind %q for instrument %q", agg.Kind(), desc.Name())
+	}
+
+	return pb, nil
+}
+
+func numberDataPoint(attrs []*commonpb.KeyValue, desc *metric.Descriptor, value core.Number) *metricpb.NumberDataPoint {
+	point := &metricpb.NumberDataPoint{
+		Attributes: attrs,
+	}
+	if desc.NumberKind() == core.Int64NumberKind {
+		point.Value = &metricpb.NumberDataPoint_AsInt{AsInt: value.AsInt64()}
+	} else {
+		point.Value = &metricpb.NumberDataPoint_AsDouble{AsDouble: value.AsFloat64()}
+	}
+	return point
+}
+
+// isMonotonicInstrumentKind reports whether a Sum aggregation backed
+// by the given InstrumentKind is monotonic. UpDownCounter and
+// UpDownSumObserver are the only instrument kinds that back a Sum
+// aggregation but permit decreasing values; every other kind that
+// reaches here (Counter, SumObserver) only ever increases.
+func isMonotonicInstrumentKind(kind metric.InstrumentKind) bool {
+	switch kind {
+	case metric.UpDownCounterInstrumentKind, metric.UpDownSumObserverInstrumentKind:
+		return false
+	default:
+		return true
+	}
+}
+
+func numberAsFloat64(n core.Number, kind core.NumberKind) float64 {
+	if kind == core.Int64NumberKind {
+		return float64(n.AsInt64())
+	}
+	return n.AsFloat64()
+}
+
+func toAggregationTemporality(kind export.ExportKind) metricpb.AggregationTemporality {
+	switch kind {
+	case export.DeltaExportKind:
+		return metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	case export.CumulativeExportKind:
+		return metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+	default:
+		return metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_UNSPECIFIED
+	}
+}
+
+func toAttributes(labels *attribute.Set) []*commonpb.KeyValue {
+	if labels == nil || labels.Len() == 0 {
+		return nil
+	}
+	kvs := make([]*commonpb.KeyValue, 0, labels.Len())
+	iter := labels.Iter()
+	for iter.Next() {
+		kvs = append(kvs, toKeyValue(iter.Label()))
+	}
+	return kvs
+}
+
+func resourceToPB(res *resource.Resource) *resourcepb.Resource {
+	if res == nil {
+		return nil
+	}
+	attrs := res.Attributes()
+	kvs := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		kvs = append(kvs, toKeyValue(kv))
+	}
+	return &resourcepb.Resource{Attributes: kvs}
+}
+
+func toKeyValue(kv core.KeyValue) *commonpb.KeyValue {
+	pb := &commonpb.KeyValue{Key: string(kv.Key)}
+	switch kv.Value.Type() {
+	case core.BOOL:
+		pb.Value = &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: kv.Value.AsBool()}}
+	case core.INT64, core.INT32:
+		pb.Value = &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: kv.Value.AsInt64()}}
+	case core.FLOAT64, core.FLOAT32:
+		pb.Value = &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: kv.Value.AsFloat64()}}
+	default:
+		pb.Value = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: kv.Value.Emit()}}
+	}
+	return pb
+}
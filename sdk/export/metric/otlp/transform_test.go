@@ -0,0 +1,229 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"go.opentelemetry.io/otel/api/attribute"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+)
+
+type fakeSumAgg struct {
+	sum core.Number
+}
+
+func (fakeSumAgg) Kind() aggregation.Kind      { return aggregation.SumKind }
+func (a fakeSumAgg) Sum() (core.Number, error) { return a.sum, nil }
+
+type fakeLastValueAgg struct {
+	value core.Number
+}
+
+func (fakeLastValueAgg) Kind() aggregation.Kind { return aggregation.LastValueKind }
+func (a fakeLastValueAgg) LastValue() (core.Number, time.Time, error) {
+	return a.value, time.Time{}, nil
+}
+
+type fakeHistogramAgg struct {
+	sum     core.Number
+	count   int64
+	buckets aggregation.Buckets
+}
+
+func (fakeHistogramAgg) Kind() aggregation.Kind                    { return aggregation.HistogramKind }
+func (a fakeHistogramAgg) Sum() (core.Number, error)               { return a.sum, nil }
+func (a fakeHistogramAgg) Count() (int64, error)                   { return a.count, nil }
+func (a fakeHistogramAgg) Histogram() (aggregation.Buckets, error) { return a.buckets, nil }
+
+type fakeMinMaxSumCountAgg struct {
+	min, max, sum core.Number
+	count         int64
+}
+
+func (fakeMinMaxSumCountAgg) Kind() aggregation.Kind      { return aggregation.MinMaxSumCountKind }
+func (a fakeMinMaxSumCountAgg) Min() (core.Number, error) { return a.min, nil }
+func (a fakeMinMaxSumCountAgg) Max() (core.Number, error) { return a.max, nil }
+func (a fakeMinMaxSumCountAgg) Sum() (core.Number, error) { return a.sum, nil }
+func (a fakeMinMaxSumCountAgg) Count() (int64, error)     { return a.count, nil }
+
+func TestRecordToMetricSum(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet()
+
+	pb, err := recordToMetric(desc, &labels, fakeSumAgg{sum: core.NewInt64Number(5)}, export.CumulativeExportKind)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sum, ok := pb.Data.(*metricpb.Metric_Sum)
+	if !ok {
+		t.Fatalf("expected Metric_Sum data, got %T", pb.Data)
+	}
+	if got := sum.Sum.DataPoints[0].GetAsInt(); got != 5 {
+		t.Fatalf("expected data point value 5, got %d", got)
+	}
+	if sum.Sum.AggregationTemporality != metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+		t.Fatalf("expected cumulative temporality, got %v", sum.Sum.AggregationTemporality)
+	}
+}
+
+func TestRecordToMetricLastValue(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet()
+
+	pb, err := recordToMetric(desc, &labels, fakeLastValueAgg{value: core.NewInt64Number(7)}, export.PassThroughExportKind)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gauge, ok := pb.Data.(*metricpb.Metric_Gauge)
+	if !ok {
+		t.Fatalf("expected Metric_Gauge data, got %T", pb.Data)
+	}
+	if got := gauge.Gauge.DataPoints[0].GetAsInt(); got != 7 {
+		t.Fatalf("expected data point value 7, got %d", got)
+	}
+}
+
+func TestRecordToMetricHistogram(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet()
+	buckets := aggregation.Buckets{Boundaries: []float64{1, 2}, Counts: []uint64{1, 2, 3}}
+
+	pb, err := recordToMetric(desc, &labels, fakeHistogramAgg{
+		sum:     core.NewInt64Number(6),
+		count:   6,
+		buckets: buckets,
+	}, export.CumulativeExportKind)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hist, ok := pb.Data.(*metricpb.Metric_Histogram)
+	if !ok {
+		t.Fatalf("expected Metric_Histogram data, got %T", pb.Data)
+	}
+	dp := hist.Histogram.DataPoints[0]
+	if dp.Count != 6 {
+		t.Fatalf("expected count 6, got %d", dp.Count)
+	}
+	if len(dp.BucketCounts) != 3 || len(dp.ExplicitBounds) != 2 {
+		t.Fatalf("expected buckets to be passed through unmodified, got %v / %v", dp.BucketCounts, dp.ExplicitBounds)
+	}
+}
+
+func TestRecordToMetricMinMaxSumCount(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet()
+
+	pb, err := recordToMetric(desc, &labels, fakeMinMaxSumCountAgg{
+		min: core.NewInt64Number(1), max: core.NewInt64Number(9), sum: core.NewInt64Number(10), count: 2,
+	}, export.CumulativeExportKind)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sum, ok := pb.Data.(*metricpb.Metric_Sum)
+	if !ok {
+		t.Fatalf("expected MinMaxSumCount to be exported as a Sum, got %T", pb.Data)
+	}
+	if sum.Sum.IsMonotonic {
+		t.Fatalf("expected a MinMaxSumCount sum to be reported as non-monotonic")
+	}
+	if got := sum.Sum.DataPoints[0].GetAsInt(); got != 10 {
+		t.Fatalf("expected data point value 10, got %d", got)
+	}
+}
+
+func TestIsMonotonicInstrumentKind(t *testing.T) {
+	cases := []struct {
+		kind metric.InstrumentKind
+		want bool
+	}{
+		{metric.CounterInstrumentKind, true},
+		{metric.UpDownCounterInstrumentKind, false},
+		{metric.SumObserverInstrumentKind, true},
+		{metric.UpDownSumObserverInstrumentKind, false},
+	}
+	for _, c := range cases {
+		if got := isMonotonicInstrumentKind(c.kind); got != c.want {
+			t.Errorf("isMonotonicInstrumentKind(%v) = %v, want %v", c.kind, got, c.want)
+		}
+	}
+}
+
+type fakeCheckpointSet struct {
+	records []export.Record
+}
+
+func (f fakeCheckpointSet) ForEach(fn func(export.Record) error) error {
+	for _, r := range f.records {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ export.CheckpointSet = fakeCheckpointSet{}
+
+func TestCheckpointSetToResourceMetricsGroupsByResourceAndLibrary(t *testing.T) {
+	descA := new(metric.Descriptor)
+	descB := new(metric.Descriptor)
+	labels := attribute.NewSet()
+
+	cps := fakeCheckpointSet{records: []export.Record{
+		export.NewRecord(descA, &labels, fakeSumAgg{sum: core.NewInt64Number(1)}),
+		export.NewRecord(descB, &labels, fakeSumAgg{sum: core.NewInt64Number(2)}),
+	}}
+
+	rms, err := checkpointSetToResourceMetrics(cps, export.NewCumulativeSelector())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rms) != 1 {
+		t.Fatalf("expected both records to group into a single ResourceMetrics (same nil resource/library), got %d", len(rms))
+	}
+	metrics := rms[0].InstrumentationLibraryMetrics[0].Metrics
+	if len(metrics) != 2 {
+		t.Fatalf("expected two distinct metrics, got %d", len(metrics))
+	}
+}
+
+func TestCheckpointSetToResourceMetricsPropagatesError(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet()
+
+	// An aggregator that does not implement aggregation.Aggregation
+	// should produce an error rather than a panic.
+	cps := fakeCheckpointSet{records: []export.Record{
+		export.NewRecord(desc, &labels, nonAggregation{}),
+	}}
+
+	if _, err := checkpointSetToResourceMetrics(cps, export.NewCumulativeSelector()); err == nil {
+		t.Fatalf("expected an error for a non-aggregation.Aggregation aggregator")
+	}
+}
+
+type nonAggregation struct{}
+
+func (nonAggregation) Update(context.Context, core.Number, *metric.Descriptor) error { return nil }
+func (nonAggregation) Checkpoint(context.Context, *metric.Descriptor)                {}
+func (nonAggregation) Merge(export.Aggregator, *metric.Descriptor) error             { return nil }
+func (nonAggregation) SynchronizedMove(export.Aggregator, *metric.Descriptor) error  { return nil }
@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/api/attribute"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestNewRecordHasNoResource(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet()
+	agg := &sumAggregator{}
+
+	r := NewRecord(desc, &labels, agg)
+
+	if r.Resource() != nil {
+		t.Fatalf("expected NewRecord to attach no Resource, got %v", r.Resource())
+	}
+	if r.Descriptor() != desc {
+		t.Fatalf("expected Descriptor to round-trip")
+	}
+	if r.Aggregator() != Aggregator(agg) {
+		t.Fatalf("expected Aggregator to round-trip")
+	}
+}
+
+func TestNewRecordWithResourceAttachesResource(t *testing.T) {
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet()
+	res := &resource.Resource{}
+	agg := &sumAggregator{}
+
+	r := NewRecordWithResource(desc, &labels, res, agg)
+
+	if r.Resource() != res {
+		t.Fatalf("expected NewRecordWithResource to attach the given Resource")
+	}
+	if !r.Labels().Equals(&labels) {
+		t.Fatalf("expected Labels to round-trip")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric // import "go.opentelemetry.io/otel/sdk/export/metric"
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel/api/attribute"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+)
+
+// statefulMergeKey identifies a single (descriptor, labels) series
+// across collection rounds.
+type statefulMergeKey struct {
+	descriptor *metric.Descriptor
+	labels     attribute.Distinct
+}
+
+// StatefulMerger accumulates a running, cumulative aggregator per
+// (descriptor, labels) series across collection rounds. A Batcher
+// whose ExportKindSelector selects CumulativeExportKind for a
+// non-LastValue aggregation uses this to turn each collection's
+// delta checkpoint into a cumulative total, by merging the delta
+// into the running aggregator it keeps for that series.
+//
+// A zero-valued StatefulMerger is ready to use: Merge lazily
+// initializes the underlying map on first use, and ForEach on an
+// empty StatefulMerger simply sees no series, so
+// NewStatefulMerger is only a convenience, not a requirement.
+// StatefulMerger is also safe for concurrent use; Merge may be called
+// from Batcher.Process while a collection is in single-threaded
+// context, but the lock also makes it safe to share across
+// collections that overlap with FinishedCollection.
+type StatefulMerger struct {
+	lock  sync.Mutex
+	state map[statefulMergeKey]*statefulMergeEntry
+}
+
+type statefulMergeEntry struct {
+	labels *attribute.Set
+	agg    Aggregator
+}
+
+// NewStatefulMerger returns a StatefulMerger with no accumulated
+// state.
+func NewStatefulMerger() *StatefulMerger {
+	return &StatefulMerger{
+		state: map[statefulMergeKey]*statefulMergeEntry{},
+	}
+}
+
+// Merge folds record's checkpointed (delta) Aggregator into the
+// running cumulative Aggregator this StatefulMerger keeps for
+// record's (descriptor, labels) series, allocating that running
+// Aggregator via newAgg the first time the series is seen. It
+// returns the running Aggregator, whose checkpoint now reflects the
+// cumulative value for the series.
+//
+// Merge must not be used for LastValue aggregations: LastValue
+// already reports its last-set value across checkpoints and has no
+// well-defined Merge semantics for a running total.
+func (m *StatefulMerger) Merge(record Record, newAgg func() Aggregator) (Aggregator, error) {
+	if record.Aggregator() == nil {
+		return nil, nil
+	}
+	if agg, ok := record.Aggregator().(aggregation.Aggregation); ok && agg.Kind() == aggregation.LastValueKind {
+		return record.Aggregator(), nil
+	}
+
+	key := statefulMergeKey{
+		descriptor: record.Descriptor(),
+		labels:     record.Labels().Equivalent(),
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.state == nil {
+		m.state = map[statefulMergeKey]*statefulMergeEntry{}
+	}
+	entry, ok := m.state[key]
+	if !ok {
+		entry = &statefulMergeEntry{labels: record.Labels(), agg: newAgg()}
+		m.state[key] = entry
+	}
+	if err := entry.agg.Merge(record.Aggregator(), record.Descriptor()); err != nil {
+		return nil, err
+	}
+	return entry.agg, nil
+}
+
+// ForEach calls f once for every series this StatefulMerger has
+// accumulated, passing the Descriptor, label Set, and running
+// cumulative Aggregator for each. It is intended for use from a
+// stateful Batcher's CheckpointSet implementation.
+func (m *StatefulMerger) ForEach(f func(descriptor *metric.Descriptor, labels *attribute.Set, agg Aggregator)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for key, entry := range m.state {
+		f(key.descriptor, entry.labels, entry.agg)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metric
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/api/attribute"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+)
+
+// sumAggregator is a minimal Sum Aggregator used to exercise
+// StatefulMerger without a concrete aggregator implementation.
+type sumAggregator struct {
+	sum core.Number
+}
+
+func (a *sumAggregator) Update(_ context.Context, n core.Number, _ *metric.Descriptor) error {
+	a.sum += n
+	return nil
+}
+func (a *sumAggregator) Checkpoint(context.Context, *metric.Descriptor) {}
+func (a *sumAggregator) Merge(other Aggregator, _ *metric.Descriptor) error {
+	o := other.(*sumAggregator)
+	a.sum += o.sum
+	return nil
+}
+func (a *sumAggregator) SynchronizedMove(dst Aggregator, _ *metric.Descriptor) error {
+	d := dst.(*sumAggregator)
+	d.sum = a.sum
+	a.sum = 0
+	return nil
+}
+func (a *sumAggregator) Kind() aggregation.Kind    { return aggregation.SumKind }
+func (a *sumAggregator) Sum() (core.Number, error) { return a.sum, nil }
+
+var (
+	_ Aggregator      = (*sumAggregator)(nil)
+	_ aggregation.Sum = (*sumAggregator)(nil)
+)
+
+func TestStatefulMergerAccumulatesAcrossCollections(t *testing.T) {
+	merger := NewStatefulMerger()
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet(core.Key("a").String("1"))
+
+	newAgg := func() Aggregator { return &sumAggregator{} }
+
+	// First collection reports a delta of 3.
+	delta1 := &sumAggregator{sum: core.NewInt64Number(3)}
+	running, err := merger.Merge(NewRecord(desc, &labels, delta1), newAgg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := running.(*sumAggregator).sum; got != core.NewInt64Number(3) {
+		t.Fatalf("after first collection: got %v, want 3", got)
+	}
+
+	// Second collection reports a further delta of 4; the running
+	// total must reflect the cumulative sum, not just the latest
+	// delta.
+	delta2 := &sumAggregator{sum: core.NewInt64Number(4)}
+	running, err = merger.Merge(NewRecord(desc, &labels, delta2), newAgg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := running.(*sumAggregator).sum; got != core.NewInt64Number(7) {
+		t.Fatalf("after second collection: got %v, want 7 (cumulative)", got)
+	}
+
+	seen := 0
+	merger.ForEach(func(gotDesc *metric.Descriptor, gotLabels *attribute.Set, agg Aggregator) {
+		seen++
+		if gotDesc != desc {
+			t.Fatalf("ForEach: wrong descriptor")
+		}
+		if !gotLabels.Equals(&labels) {
+			t.Fatalf("ForEach: wrong labels")
+		}
+	})
+	if seen != 1 {
+		t.Fatalf("expected exactly one accumulated series, got %d", seen)
+	}
+}
+
+func TestStatefulMergerZeroValueIsUsable(t *testing.T) {
+	var merger StatefulMerger
+	desc := new(metric.Descriptor)
+	labels := attribute.NewSet(core.Key("a").String("1"))
+	newAgg := func() Aggregator { return &sumAggregator{} }
+
+	delta := &sumAggregator{sum: core.NewInt64Number(5)}
+	running, err := merger.Merge(NewRecord(desc, &labels, delta), newAgg)
+	if err != nil {
+		t.Fatalf("unexpected error merging into a zero-valued StatefulMerger: %v", err)
+	}
+	if got := running.(*sumAggregator).sum; got != core.NewInt64Number(5) {
+		t.Fatalf("got %v, want 5", got)
+	}
+
+	seen := 0
+	merger.ForEach(func(*metric.Descriptor, *attribute.Set, Aggregator) { seen++ })
+	if seen != 1 {
+		t.Fatalf("expected ForEach on a zero-valued StatefulMerger to see the merged series, got %d", seen)
+	}
+}
+
+func TestStatefulMergerKeepsSeriesIndependent(t *testing.T) {
+	merger := NewStatefulMerger()
+	desc := new(metric.Descriptor)
+	labelsA := attribute.NewSet(core.Key("a").String("1"))
+	labelsB := attribute.NewSet(core.Key("a").String("2"))
+	newAgg := func() Aggregator { return &sumAggregator{} }
+
+	if _, err := merger.Merge(NewRecord(desc, &labelsA, &sumAggregator{sum: core.NewInt64Number(1)}), newAgg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := merger.Merge(NewRecord(desc, &labelsB, &sumAggregator{sum: core.NewInt64Number(10)}), newAgg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	seen := map[string]core.Number{}
+	merger.ForEach(func(_ *metric.Descriptor, labels *attribute.Set, agg Aggregator) {
+		kv, _ := labels.Get(0)
+		seen[kv.Value.Emit()] = agg.(*sumAggregator).sum
+	})
+	if seen["1"] != core.NewInt64Number(1) || seen["2"] != core.NewInt64Number(10) {
+		t.Fatalf("series were not kept independent: %v", seen)
+	}
+}
+
+func TestExportKindSelectors(t *testing.T) {
+	desc := new(metric.Descriptor)
+
+	cases := []struct {
+		name     string
+		selector ExportKindSelector
+		kind     aggregation.Kind
+		want     ExportKind
+	}{
+		{"stateless/sum", NewStatelessSelector(), aggregation.SumKind, DeltaExportKind},
+		{"stateless/lastvalue", NewStatelessSelector(), aggregation.LastValueKind, PassThroughExportKind},
+		{"cumulative/sum", NewCumulativeSelector(), aggregation.SumKind, CumulativeExportKind},
+		{"cumulative/lastvalue", NewCumulativeSelector(), aggregation.LastValueKind, PassThroughExportKind},
+		{"delta/sum", NewDeltaSelector(), aggregation.SumKind, DeltaExportKind},
+		{"delta/lastvalue", NewDeltaSelector(), aggregation.LastValueKind, PassThroughExportKind},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.selector.ExportKindFor(desc, c.kind); got != c.want {
+				t.Fatalf("got %v, want %v", got, c.want)
+			}
+			if !c.want.MatchesAggregationKind(c.kind) {
+				t.Fatalf("MatchesAggregationKind(%v) on %v returned false", c.kind, c.want)
+			}
+		})
+	}
+}